@@ -23,6 +23,7 @@ type Canvas struct {
 	Width      int              `yaml:"width"`
 	Height     int              `yaml:"height"`
 	Background Background       `yaml:"background"`
+	Defs       *Defs            `yaml:"defs,omitempty"`
 	Elements   []ElementWrapper `yaml:"elements"`
 }
 
@@ -35,6 +36,26 @@ func (c *Canvas) GetElements() []Element {
 	return elements
 }
 
+// Render writes the full SVG document: the defs block (if any), the
+// background, and every top-level element, in that order.
+func (c *Canvas) Render(canvas *svg.SVG) {
+	canvas.Start(c.Width, c.Height)
+	defer canvas.End()
+
+	c.Defs.render(canvas)
+
+	if c.Background.Color != "" {
+		canvas.Rect(0, 0, c.Width, c.Height, fmt.Sprintf("fill:%s;", c.Background.Color))
+	}
+	if c.Background.Image != "" {
+		canvas.Image(0, 0, c.Width, c.Height, c.Background.Image)
+	}
+
+	for _, elem := range c.GetElements() {
+		elem.Render(canvas)
+	}
+}
+
 // Background represents the canvas background.
 type Background struct {
 	Color string `yaml:"color,omitempty"`
@@ -91,6 +112,42 @@ func (ew *ElementWrapper) UnmarshalYAML(unmarshal func(interface{}) error) error
 			return err
 		}
 		ew.Element = &grp
+	case "circle":
+		var circ Circle
+		if err := mapToStruct(raw, &circ); err != nil {
+			return err
+		}
+		ew.Element = &circ
+	case "ellipse":
+		var ell Ellipse
+		if err := mapToStruct(raw, &ell); err != nil {
+			return err
+		}
+		ew.Element = &ell
+	case "polygon":
+		var poly Polygon
+		if err := mapToStruct(raw, &poly); err != nil {
+			return err
+		}
+		ew.Element = &poly
+	case "polyline":
+		var poly Polyline
+		if err := mapToStruct(raw, &poly); err != nil {
+			return err
+		}
+		ew.Element = &poly
+	case "path":
+		var path Path
+		if err := mapToStruct(raw, &path); err != nil {
+			return err
+		}
+		ew.Element = &path
+	case "use":
+		var use Use
+		if err := mapToStruct(raw, &use); err != nil {
+			return err
+		}
+		ew.Element = &use
 	default:
 		return fmt.Errorf("unsupported element type: %s", typ)
 	}
@@ -229,6 +286,251 @@ func (g *Group) Render(canvas *svg.SVG) {
 	canvas.Gend()
 }
 
+// Circle represents an SVG circle.
+type Circle struct {
+	Type        string     `yaml:"type"`
+	ID          string     `yaml:"id,omitempty"`
+	CX          int        `yaml:"cx"`
+	CY          int        `yaml:"cy"`
+	R           int        `yaml:"r"`
+	Fill        string     `yaml:"fill,omitempty"`
+	Stroke      string     `yaml:"stroke,omitempty"`
+	StrokeWidth int        `yaml:"stroke_width,omitempty"`
+	Transform   *Transform `yaml:"transform,omitempty"`
+}
+
+// Render renders the circle onto the SVG canvas.
+func (c *Circle) Render(canvas *svg.SVG) {
+	styles := buildStyles(c.Fill, c.Stroke, c.StrokeWidth)
+	if c.Transform != nil {
+		canvas.Gtransform(buildTransform(c.Transform))
+	}
+	canvas.Circle(c.CX, c.CY, c.R, styles)
+	if c.Transform != nil {
+		canvas.Gend()
+	}
+}
+
+// Ellipse represents an SVG ellipse.
+type Ellipse struct {
+	Type        string     `yaml:"type"`
+	ID          string     `yaml:"id,omitempty"`
+	CX          int        `yaml:"cx"`
+	CY          int        `yaml:"cy"`
+	RX          int        `yaml:"rx"`
+	RY          int        `yaml:"ry"`
+	Fill        string     `yaml:"fill,omitempty"`
+	Stroke      string     `yaml:"stroke,omitempty"`
+	StrokeWidth int        `yaml:"stroke_width,omitempty"`
+	Transform   *Transform `yaml:"transform,omitempty"`
+}
+
+// Render renders the ellipse onto the SVG canvas.
+func (e *Ellipse) Render(canvas *svg.SVG) {
+	styles := buildStyles(e.Fill, e.Stroke, e.StrokeWidth)
+	if e.Transform != nil {
+		canvas.Gtransform(buildTransform(e.Transform))
+	}
+	canvas.Ellipse(e.CX, e.CY, e.RX, e.RY, styles)
+	if e.Transform != nil {
+		canvas.Gend()
+	}
+}
+
+// Polygon represents a closed, filled SVG polygon.
+type Polygon struct {
+	Type        string     `yaml:"type"`
+	ID          string     `yaml:"id,omitempty"`
+	Points      [][2]int   `yaml:"points"` // [[x0,y0],[x1,y1],...]
+	Fill        string     `yaml:"fill,omitempty"`
+	Stroke      string     `yaml:"stroke,omitempty"`
+	StrokeWidth int        `yaml:"stroke_width,omitempty"`
+	Transform   *Transform `yaml:"transform,omitempty"`
+}
+
+// Render renders the polygon onto the SVG canvas.
+func (p *Polygon) Render(canvas *svg.SVG) {
+	styles := buildStyles(p.Fill, p.Stroke, p.StrokeWidth)
+	xs, ys := splitPoints(p.Points)
+	if p.Transform != nil {
+		canvas.Gtransform(buildTransform(p.Transform))
+	}
+	canvas.Polygon(xs, ys, styles)
+	if p.Transform != nil {
+		canvas.Gend()
+	}
+}
+
+// Polyline represents an open, unclosed SVG polyline.
+type Polyline struct {
+	Type        string     `yaml:"type"`
+	ID          string     `yaml:"id,omitempty"`
+	Points      [][2]int   `yaml:"points"` // [[x0,y0],[x1,y1],...]
+	Fill        string     `yaml:"fill,omitempty"`
+	Stroke      string     `yaml:"stroke,omitempty"`
+	StrokeWidth int        `yaml:"stroke_width,omitempty"`
+	Transform   *Transform `yaml:"transform,omitempty"`
+}
+
+// Render renders the polyline onto the SVG canvas.
+func (p *Polyline) Render(canvas *svg.SVG) {
+	styles := buildStyles(p.Fill, p.Stroke, p.StrokeWidth)
+	xs, ys := splitPoints(p.Points)
+	if p.Transform != nil {
+		canvas.Gtransform(buildTransform(p.Transform))
+	}
+	canvas.Polyline(xs, ys, styles)
+	if p.Transform != nil {
+		canvas.Gend()
+	}
+}
+
+// splitPoints splits a list of [x,y] pairs into separate x and y slices, the
+// form svgo's Polygon/Polyline expect.
+func splitPoints(points [][2]int) ([]int, []int) {
+	xs := make([]int, len(points))
+	ys := make([]int, len(points))
+	for i, p := range points {
+		xs[i] = p[0]
+		ys[i] = p[1]
+	}
+	return xs, ys
+}
+
+// Path represents an SVG path. D is passed straight through to the "d"
+// attribute and accepts the usual path command subset: M, L, H, V, C, S, Q,
+// T, A, Z (both absolute and lowercase relative forms).
+type Path struct {
+	Type        string     `yaml:"type"`
+	ID          string     `yaml:"id,omitempty"`
+	D           string     `yaml:"d"`
+	Fill        string     `yaml:"fill,omitempty"`
+	Stroke      string     `yaml:"stroke,omitempty"`
+	StrokeWidth int        `yaml:"stroke_width,omitempty"`
+	Transform   *Transform `yaml:"transform,omitempty"`
+}
+
+// Render renders the path onto the SVG canvas.
+func (p *Path) Render(canvas *svg.SVG) {
+	styles := buildStyles(p.Fill, p.Stroke, p.StrokeWidth)
+	if p.Transform != nil {
+		canvas.Gtransform(buildTransform(p.Transform))
+	}
+	canvas.Path(p.D, styles)
+	if p.Transform != nil {
+		canvas.Gend()
+	}
+}
+
+// Use emits a <use> element at (x, y) whose href is "#" + Ref. The id fields
+// of this DSL's elements are not written to the output, so Ref only resolves
+// against ids present in the rendered document, such as the defs entries.
+type Use struct {
+	Type      string     `yaml:"type"`
+	ID        string     `yaml:"id,omitempty"`
+	Ref       string     `yaml:"ref"` // id of the def to reuse, without "#"
+	X         int        `yaml:"x"`
+	Y         int        `yaml:"y"`
+	Transform *Transform `yaml:"transform,omitempty"`
+}
+
+// Render renders the use reference onto the SVG canvas.
+func (u *Use) Render(canvas *svg.SVG) {
+	if u.Transform != nil {
+		canvas.Gtransform(buildTransform(u.Transform))
+	}
+	canvas.Use(u.X, u.Y, "#"+u.Ref)
+	if u.Transform != nil {
+		canvas.Gend()
+	}
+}
+
+// Defs holds reusable definitions that elements reference from their fill or
+// stroke as "url(#id)", or from a use: block as ref.
+type Defs struct {
+	LinearGradients []LinearGradient `yaml:"linearGradient,omitempty"`
+	RadialGradients []RadialGradient `yaml:"radialGradient,omitempty"`
+	Patterns        []Pattern        `yaml:"pattern,omitempty"`
+}
+
+// GradientStop is one color stop of a linear or radial gradient. Opacity
+// defaults to fully opaque (1) when omitted.
+type GradientStop struct {
+	Offset  uint8   `yaml:"offset"` // percentage along the gradient, 0-100
+	Color   string  `yaml:"color"`
+	Opacity float64 `yaml:"opacity,omitempty"`
+}
+
+// LinearGradient is a `defs:` entry referenced elsewhere as fill/stroke:
+// "url(#id)". Coordinates are percentages (0-100) of the gradient's bounding box.
+type LinearGradient struct {
+	ID    string         `yaml:"id"`
+	X1    uint8          `yaml:"x1"`
+	Y1    uint8          `yaml:"y1"`
+	X2    uint8          `yaml:"x2"`
+	Y2    uint8          `yaml:"y2"`
+	Stops []GradientStop `yaml:"stops"`
+}
+
+// RadialGradient is a `defs:` entry referenced elsewhere as fill/stroke:
+// "url(#id)". Coordinates are percentages (0-100) of the gradient's bounding box.
+type RadialGradient struct {
+	ID    string         `yaml:"id"`
+	CX    uint8          `yaml:"cx"`
+	CY    uint8          `yaml:"cy"`
+	R     uint8          `yaml:"r"`
+	FX    uint8          `yaml:"fx"`
+	FY    uint8          `yaml:"fy"`
+	Stops []GradientStop `yaml:"stops"`
+}
+
+// Pattern is a `defs:` entry referenced elsewhere as fill/stroke: "url(#id)".
+// Link is the href of the content tiled inside the pattern cell.
+type Pattern struct {
+	ID     string `yaml:"id"`
+	X      int    `yaml:"x"`
+	Y      int    `yaml:"y"`
+	Width  int    `yaml:"width"`
+	Height int    `yaml:"height"`
+	Link   string `yaml:"link"`
+}
+
+// render emits every gradient and pattern def inside a single <defs> block.
+// d may be nil, in which case it is a no-op.
+func (d *Defs) render(canvas *svg.SVG) {
+	if d == nil {
+		return
+	}
+
+	canvas.Def()
+	for _, lg := range d.LinearGradients {
+		canvas.LinearGradient(lg.ID, lg.X1, lg.Y1, lg.X2, lg.Y2, toOffcolors(lg.Stops))
+	}
+	for _, rg := range d.RadialGradients {
+		canvas.RadialGradient(rg.ID, rg.CX, rg.CY, rg.R, rg.FX, rg.FY, toOffcolors(rg.Stops))
+	}
+	for _, p := range d.Patterns {
+		canvas.Pattern(p.ID, p.X, p.Y, p.Width, p.Height, "user")
+		canvas.Image(0, 0, p.Width, p.Height, p.Link)
+		canvas.PatternEnd()
+	}
+	canvas.DefEnd()
+}
+
+// toOffcolors converts our YAML-facing GradientStop list to svgo's
+// Offcolor, defaulting an omitted opacity to fully opaque.
+func toOffcolors(stops []GradientStop) []svg.Offcolor {
+	sc := make([]svg.Offcolor, len(stops))
+	for i, s := range stops {
+		opacity := s.Opacity
+		if opacity == 0 {
+			opacity = 1
+		}
+		sc[i] = svg.Offcolor{Offset: s.Offset, Color: s.Color, Opacity: opacity}
+	}
+	return sc
+}
+
 // Transform represents transformations applied to SVG elements.
 type Transform struct {
 	Translate []int     `yaml:"translate,omitempty"` // [x, y]
@@ -10,44 +10,61 @@ import (
 func NewAddCommand() *cobra.Command {
 	var branchName string
 	var forceOverwrite bool
+	var withDeps bool
+	var dryRun bool
 
 	cmd := &cobra.Command{
-		Use:   "add <workspace-name> <repo-name>",
-		Short: "Add a repository to an existing workspace",
-		Long: `Add a repository to an existing workspace and create the necessary branch.
+		Use:   "add <workspace-name> <repo-name> [<repo-name>...]",
+		Short: "Add one or more repositories to an existing workspace",
+		Long: `Add one or more repositories to an existing workspace and create the necessary branches.
 
 This command:
 - Loads the specified workspace configuration
-- Finds the specified repository in the registry
-- Creates a worktree for the repository using the workspace's branch
-- Updates the workspace configuration to include the new repository
-- Creates or updates go.work file if the workspace has Go repositories
+- Finds the specified repositories in the registry
+- With --with-deps, walks each repo's go.mod (and the workspace's existing
+  go.work replace directives) to also pull in any registered repo it depends on
+- Topologically sorts the full set of repos so dependencies are checked out
+  before their consumers
+- Creates a worktree for each repository using the workspace's branch
+- Updates the workspace configuration to include the new repositories
+- Rewrites go.work exactly once, after every worktree has been created
 
 Examples:
   # Add a repository to an existing workspace
   workspace-manager add my-feature my-new-repo
 
+  # Add several repositories in one invocation
+  workspace-manager add my-feature repo-a repo-b repo-c
+
+  # Add a repository along with everything it depends on
+  workspace-manager add my-feature my-new-repo --with-deps
+
   # Add a repository with a different branch name
   workspace-manager add my-feature my-new-repo --branch feature/different-branch
 
   # Force overwrite if the branch already exists
-  workspace-manager add my-feature my-new-repo --force`,
-		Args: cobra.ExactArgs(2),
+  workspace-manager add my-feature my-new-repo --force
+
+  # Preview the worktree, config, and go.work changes without making them
+  workspace-manager add my-feature repo-a repo-b --dry-run`,
+		Args: cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			workspaceName := args[0]
-			repoName := args[1]
+			repoNames := args[1:]
 
 			wm, err := wsm.NewWorkspaceManager()
 			if err != nil {
 				return errors.Wrap(err, "failed to create workspace manager")
 			}
 
-			return wm.AddRepositoryToWorkspace(cmd.Context(), workspaceName, repoName, branchName, forceOverwrite)
+			return wm.AddRepositoriesToWorkspace(cmd.Context(), workspaceName, repoNames, branchName, forceOverwrite, withDeps, dryRun)
 		},
 	}
 
 	cmd.Flags().StringVarP(&branchName, "branch", "b", "", "Branch name to use (defaults to workspace's branch)")
 	cmd.Flags().BoolVarP(&forceOverwrite, "force", "f", false, "Force overwrite if branch already exists")
+	cmd.Flags().BoolVar(&withDeps, "with-deps", false, "Also add every registered repo the targets transitively depend on")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the planned worktree/config/go.work changes without applying them")
 
 	return cmd
 }
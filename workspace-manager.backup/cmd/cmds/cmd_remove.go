@@ -10,44 +10,62 @@ import (
 func NewRemoveCommand() *cobra.Command {
 	var force bool
 	var removeFiles bool
+	var withDeps bool
+	var dryRun bool
 
 	cmd := &cobra.Command{
-		Use:   "remove <workspace-name> <repo-name>",
-		Short: "Remove a repository from an existing workspace",
-		Long: `Remove a repository from an existing workspace and clean up its worktree.
+		Use:   "remove <workspace-name> <repo-name> [<repo-name>...]",
+		Short: "Remove one or more repositories from an existing workspace",
+		Long: `Remove one or more repositories from an existing workspace and clean up their worktrees.
 
 This command:
 - Loads the specified workspace configuration
-- Removes the specified repository's worktree using git worktree remove
-- Updates the workspace configuration to exclude the repository
-- Updates go.work file if the workspace has Go repositories
-- Optionally removes the repository directory from the workspace
+- Refuses to remove a repo that another repo still in the workspace
+  replace-depends on, listing the blocking consumers, unless --force is set
+- With --with-deps, also removes any repo in the workspace whose only
+  remaining dependency is one of the targets
+- Removes each repository's worktree using git worktree remove
+- Updates the workspace configuration to exclude the repositories
+- Rewrites go.work exactly once, after every worktree has been removed
+- Optionally removes the repository directories from the workspace
 
 Examples:
   # Remove a repository from a workspace
   workspace-manager remove my-feature my-old-repo
 
-  # Force remove a repository (removes worktree even with uncommitted changes)
+  # Remove several repositories in one invocation
+  workspace-manager remove my-feature repo-a repo-b
+
+  # Remove a repository and anything that only existed to support it
+  workspace-manager remove my-feature my-old-repo --with-deps
+
+  # Force remove a repository (removes worktree even with uncommitted changes,
+  # and skips the blocking-consumer check)
   workspace-manager remove my-feature my-old-repo --force
 
   # Remove repository and its directory from workspace
-  workspace-manager remove my-feature my-old-repo --remove-files`,
-		Args: cobra.ExactArgs(2),
+  workspace-manager remove my-feature my-old-repo --remove-files
+
+  # Preview the worktree, config, and go.work changes without making them
+  workspace-manager remove my-feature repo-a repo-b --dry-run`,
+		Args: cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			workspaceName := args[0]
-			repoName := args[1]
+			repoNames := args[1:]
 
 			wm, err := wsm.NewWorkspaceManager()
 			if err != nil {
 				return errors.Wrap(err, "failed to create workspace manager")
 			}
 
-			return wm.RemoveRepositoryFromWorkspace(cmd.Context(), workspaceName, repoName, force, removeFiles)
+			return wm.RemoveRepositoriesFromWorkspace(cmd.Context(), workspaceName, repoNames, force, removeFiles, withDeps, dryRun)
 		},
 	}
 
-	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force remove worktree even with uncommitted changes")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force remove worktree even with uncommitted changes, and skip the blocking-consumer check")
 	cmd.Flags().BoolVar(&removeFiles, "remove-files", false, "Remove the repository directory from workspace")
+	cmd.Flags().BoolVar(&withDeps, "with-deps", false, "Also remove any repo in the workspace left exclusively depending on the targets")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the planned worktree/config/go.work changes without applying them")
 
 	return cmd
 }
@@ -0,0 +1,69 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/go-go-golems/workspace-manager/pkg/wsm"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewRestoreCommand creates the restore command
+func NewRestoreCommand() *cobra.Command {
+	var name string
+	var latest bool
+	var diff bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <workspace-name>",
+		Short: "Restore a workspace to a previously recorded snapshot",
+		Long: `Re-check out every repo in a workspace to the HEAD SHA recorded in a
+snapshot, as a detached HEAD, creating the worktree first (via the same code
+path "add" uses, on the recorded branch) if it no longer exists, then rewrite
+go.work from the snapshot's contents. The recorded branch is written back to
+the workspace configuration.
+
+Examples:
+  # Restore a workspace to a specific named snapshot
+  workspace-manager restore my-feature --name before-refactor
+
+  # Restore a workspace to its most recent snapshot
+  workspace-manager restore my-feature --latest
+
+  # Preview what restoring would change without applying it
+  workspace-manager restore my-feature --latest --diff`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspaceName := args[0]
+
+			if name == "" && !latest {
+				return errors.New("either --name or --latest is required")
+			}
+
+			wm, err := wsm.NewWorkspaceManager()
+			if err != nil {
+				return errors.Wrap(err, "failed to create workspace manager")
+			}
+
+			snap, err := wm.LoadSnapshot(workspaceName, name)
+			if err != nil {
+				return errors.Wrap(err, "failed to load snapshot")
+			}
+
+			if err := wm.RestoreWorkspace(cmd.Context(), workspaceName, snap, diff); err != nil {
+				return errors.Wrap(err, "failed to restore workspace")
+			}
+
+			if !diff {
+				fmt.Printf("Restored workspace %q to snapshot %q\n", workspaceName, snap.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the snapshot to restore")
+	cmd.Flags().BoolVar(&latest, "latest", false, "Restore the most recently recorded snapshot")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Print what would change without applying it")
+
+	return cmd
+}
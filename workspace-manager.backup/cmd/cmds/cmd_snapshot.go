@@ -0,0 +1,53 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/go-go-golems/workspace-manager/pkg/wsm"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewSnapshotCommand creates the snapshot command
+func NewSnapshotCommand() *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot <workspace-name>",
+		Short: "Record a point-in-time checkpoint of a workspace",
+		Long: `Record the current branch, HEAD SHA, and worktree path of every repo in a
+workspace, along with the full go.work contents, into a timestamped JSON file
+under the workspace's state directory.
+
+This gives you a reliable "known good" checkpoint to restore to before a
+large cross-repo refactor.
+
+Examples:
+  # Snapshot a workspace under an automatically generated, timestamped name
+  workspace-manager snapshot my-feature
+
+  # Snapshot a workspace under a specific name
+  workspace-manager snapshot my-feature --name before-refactor`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspaceName := args[0]
+
+			wm, err := wsm.NewWorkspaceManager()
+			if err != nil {
+				return errors.Wrap(err, "failed to create workspace manager")
+			}
+
+			snap, err := wm.SnapshotWorkspace(cmd.Context(), workspaceName, name)
+			if err != nil {
+				return errors.Wrap(err, "failed to snapshot workspace")
+			}
+
+			fmt.Printf("Snapshotted %d repo(s) in workspace %q as %q\n", len(snap.Repos), workspaceName, snap.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name for the snapshot (defaults to the current timestamp)")
+
+	return cmd
+}
@@ -0,0 +1,537 @@
+package wsm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+// AddRepositoriesToWorkspace adds one or more repositories to workspaceName
+// in a single transactional operation. When withDeps is true, each target's
+// go.mod and the workspace's existing go.work replace directives are walked
+// to pull in every module it depends on that is also registered, so the
+// resulting set of worktrees is self-consistent. Repos are created in
+// dependency order (leaves first) and go.work is rewritten exactly once at
+// the end, instead of once per repo.
+//
+// If dryRun is true, the planned sequence of mutations is printed and
+// nothing is touched. Otherwise each step (worktree creation, config update,
+// go.work rewrite) is journaled as it runs; if any step fails, every prior
+// step in this call is rolled back.
+func (wm *WorkspaceManager) AddRepositoriesToWorkspace(ctx context.Context, workspaceName string, repoNames []string, branchName string, force, withDeps, dryRun bool) error {
+	ws, err := wm.loadWorkspace(workspaceName)
+	if err != nil {
+		return err
+	}
+
+	if branchName == "" {
+		branchName = ws.Branch
+	}
+
+	targets := dedupeStrings(repoNames)
+	if withDeps {
+		targets, err = wm.expandWithDependencies(ws, repoNames)
+		if err != nil {
+			return err
+		}
+	}
+
+	order, err := wm.topoSortByDependency(ws, targets)
+	if err != nil {
+		return err
+	}
+
+	if ws.Repos == nil {
+		ws.Repos = map[string]*RepoEntry{}
+	}
+
+	configBefore, configExisted, err := wm.snapshotWorkspaceFile(workspaceName)
+	if err != nil {
+		return err
+	}
+
+	var ops []operation
+	for _, repoName := range order {
+		if _, exists := ws.Repos[repoName]; exists {
+			continue
+		}
+
+		repoName := repoName
+		repo, err := wm.registry.Find(repoName)
+		if err != nil {
+			return err
+		}
+
+		ops = append(ops, operation{
+			description: fmt.Sprintf("git worktree add -B %s %s (repo %s)", branchName, filepath.Join(ws.Root, repo.Name), repoName),
+			do: func(ctx context.Context) error {
+				entry, err := wm.createWorktree(ctx, ws, repo, branchName, force)
+				if err != nil {
+					return err
+				}
+				ws.Repos[repoName] = entry
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				entry, ok := ws.Repos[repoName]
+				if !ok {
+					return nil
+				}
+				delete(ws.Repos, repoName)
+				return wm.removeWorktree(ctx, entry, true, false)
+			},
+		})
+	}
+
+	var goWorkBefore []byte
+	var goWorkExisted bool
+	ops = append(ops,
+		operation{
+			description: fmt.Sprintf("rewrite go.work for workspace %q", workspaceName),
+			do: func(ctx context.Context) error {
+				before, existed, err := wm.snapshotGoWork(ws)
+				if err != nil {
+					return err
+				}
+				goWorkBefore, goWorkExisted = before, existed
+				return wm.writeGoWork(ws)
+			},
+			undo: func(ctx context.Context) error {
+				return wm.restoreGoWork(ws, goWorkBefore, goWorkExisted)
+			},
+		},
+		operation{
+			description: fmt.Sprintf("update workspace config %q", workspaceName),
+			do:          func(ctx context.Context) error { return wm.saveWorkspace(ws) },
+			undo: func(ctx context.Context) error {
+				return wm.restoreWorkspaceFile(workspaceName, configBefore, configExisted)
+			},
+		},
+	)
+
+	return runOperations(ctx, dryRun, ops)
+}
+
+// RemoveRepositoriesFromWorkspace removes one or more repositories from
+// workspaceName in a single transactional operation. Unless force is set, it
+// refuses to remove a repo that another repo still in the workspace
+// replace-depends on, reporting the blocking consumers instead of leaving
+// go.work dangling. When withDeps is true, every repo that exclusively
+// depends on a target (and nothing else in the workspace) is removed
+// alongside it.
+//
+// If dryRun is true, the planned sequence of mutations is printed and
+// nothing is touched. Otherwise each step is journaled as it runs; if any
+// step fails, every prior step in this call is rolled back.
+func (wm *WorkspaceManager) RemoveRepositoriesFromWorkspace(ctx context.Context, workspaceName string, repoNames []string, force, removeFiles, withDeps, dryRun bool) error {
+	ws, err := wm.loadWorkspace(workspaceName)
+	if err != nil {
+		return err
+	}
+
+	targets := map[string]bool{}
+	for _, name := range repoNames {
+		targets[name] = true
+	}
+
+	if withDeps {
+		for name := range wm.exclusiveDependents(ws, repoNames) {
+			targets[name] = true
+		}
+	}
+
+	if !force {
+		for name := range targets {
+			blockers := wm.blockingConsumers(ws, name, targets)
+			if len(blockers) > 0 {
+				sort.Strings(blockers)
+				return errors.Errorf("refusing to remove %q: still replace-depended on by %v (use --force to override)", name, blockers)
+			}
+		}
+	}
+
+	// Remove in reverse dependency order so a consumer's worktree is gone
+	// before (or alongside) the module it depends on.
+	order, err := wm.topoSortByDependency(ws, mapKeys(targets))
+	if err != nil {
+		return err
+	}
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+
+	configBefore, configExisted, err := wm.snapshotWorkspaceFile(workspaceName)
+	if err != nil {
+		return err
+	}
+
+	var ops []operation
+	for _, repoName := range order {
+		entry, ok := ws.Repos[repoName]
+		if !ok {
+			continue
+		}
+
+		repoName, entry := repoName, entry
+		var previousSHA string
+		ops = append(ops, operation{
+			description: fmt.Sprintf("git worktree remove %s (repo %s)", entry.WorktreePath, repoName),
+			do: func(ctx context.Context) error {
+				sha, err := headSHA(ctx, entry.WorktreePath)
+				if err != nil {
+					return err
+				}
+				previousSHA = sha
+				if err := wm.removeWorktree(ctx, entry, force, removeFiles); err != nil {
+					return err
+				}
+				delete(ws.Repos, repoName)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				repo, err := wm.registry.Find(repoName)
+				if err != nil {
+					return err
+				}
+				restored, err := wm.createWorktree(ctx, ws, repo, entry.Branch, true)
+				if err != nil {
+					return err
+				}
+				if previousSHA != "" {
+					if err := checkoutSHA(ctx, restored.WorktreePath, previousSHA); err != nil {
+						return err
+					}
+				}
+				ws.Repos[repoName] = restored
+				return nil
+			},
+		})
+	}
+
+	var goWorkBefore []byte
+	var goWorkExisted bool
+	ops = append(ops,
+		operation{
+			description: fmt.Sprintf("rewrite go.work for workspace %q", workspaceName),
+			do: func(ctx context.Context) error {
+				before, existed, err := wm.snapshotGoWork(ws)
+				if err != nil {
+					return err
+				}
+				goWorkBefore, goWorkExisted = before, existed
+				return wm.writeGoWork(ws)
+			},
+			undo: func(ctx context.Context) error {
+				return wm.restoreGoWork(ws, goWorkBefore, goWorkExisted)
+			},
+		},
+		operation{
+			description: fmt.Sprintf("update workspace config %q", workspaceName),
+			do:          func(ctx context.Context) error { return wm.saveWorkspace(ws) },
+			undo: func(ctx context.Context) error {
+				return wm.restoreWorkspaceFile(workspaceName, configBefore, configExisted)
+			},
+		},
+	)
+
+	return runOperations(ctx, dryRun, ops)
+}
+
+// expandWithDependencies returns repoNames plus every registered repo they
+// transitively depend on, via go.mod requires and go.work replace directives.
+func (wm *WorkspaceManager) expandWithDependencies(ws *Workspace, repoNames []string) ([]string, error) {
+	seen := map[string]bool{}
+	var walk func(string) error
+	walk = func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+
+		deps, err := wm.moduleDependencies(ws, name)
+		if err != nil {
+			return err
+		}
+		for _, dep := range deps {
+			if _, ok := wm.registry.Repos[dep]; !ok {
+				continue // not a repo we manage, leave it to plain `go mod`
+			}
+			if err := walk(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, name := range repoNames {
+		if err := walk(name); err != nil {
+			return nil, err
+		}
+	}
+
+	result := mapKeys(seen)
+	sort.Strings(result)
+	return result, nil
+}
+
+// moduleDependencies returns the registered repo names that repoName depends
+// on: every repo whose module path appears in repoName's go.mod requires,
+// plus every repo that a go.work replace directive redirects one of those
+// requires to (an indirect dependency only visible through the workspace's
+// existing go.work, not repoName's own go.mod).
+func (wm *WorkspaceManager) moduleDependencies(ws *Workspace, repoName string) ([]string, error) {
+	repo, err := wm.registry.Find(repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(repo.Path, "go.mod"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read go.mod for %q", repoName)
+	}
+
+	mf, err := modfile.Parse(repo.Path+"/go.mod", data, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse go.mod for %q", repoName)
+	}
+
+	modulePathToRepo := map[string]string{}
+	for name, r := range wm.registry.Repos {
+		if mp := modulePath(r.Path); mp != "" {
+			modulePathToRepo[mp] = name
+		}
+	}
+
+	replacements, err := wm.goWorkReplacements(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var deps []string
+	addDep := func(name string) {
+		if name == "" || name == repoName || seen[name] {
+			return
+		}
+		seen[name] = true
+		deps = append(deps, name)
+	}
+
+	for _, req := range mf.Require {
+		if name, ok := modulePathToRepo[req.Mod.Path]; ok {
+			addDep(name)
+			continue
+		}
+		if dir, ok := replacements[req.Mod.Path]; ok {
+			if name, ok := wm.repoForDir(dir); ok {
+				addDep(name)
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// goWorkReplacements parses the workspace's go.work file (if any) and
+// returns a map from module path to the local directory its replace
+// directive points at. Replacements with a version (i.e. pointing at another
+// module version rather than a local checkout) are ignored, since they
+// don't correspond to a repo in this workspace.
+func (wm *WorkspaceManager) goWorkReplacements(ws *Workspace) (map[string]string, error) {
+	data, existed, err := wm.snapshotGoWork(ws)
+	if err != nil {
+		return nil, err
+	}
+	if !existed {
+		return map[string]string{}, nil
+	}
+
+	path := ws.GoWork
+	if path == "" {
+		path = filepath.Join(ws.Root, "go.work")
+	}
+
+	wf, err := modfile.ParseWork(path, data, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse go.work")
+	}
+
+	replacements := map[string]string{}
+	for _, r := range wf.Replace {
+		if r.New.Version != "" {
+			continue // points at another module version, not a local checkout
+		}
+		dir := r.New.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		replacements[r.Old.Path] = dir
+	}
+	return replacements, nil
+}
+
+// repoForDir resolves a local directory (as used by a go.work replace
+// directive) back to the registered repo rooted there, if any.
+func (wm *WorkspaceManager) repoForDir(dir string) (string, bool) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for name, r := range wm.registry.Repos {
+		absRepo, err := filepath.Abs(r.Path)
+		if err == nil && absRepo == absDir {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// topoSortByDependency orders repoNames so that every repo appears after the
+// registered repos it depends on (Kahn's algorithm). Repos outside the
+// registry's known dependency graph keep their relative input order.
+func (wm *WorkspaceManager) topoSortByDependency(ws *Workspace, repoNames []string) ([]string, error) {
+	inSet := map[string]bool{}
+	for _, name := range repoNames {
+		inSet[name] = true
+	}
+
+	deps := map[string][]string{}
+	indegree := map[string]int{}
+	for _, name := range repoNames {
+		indegree[name] = 0
+	}
+	for _, name := range repoNames {
+		ds, err := wm.moduleDependencies(ws, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range ds {
+			if !inSet[d] {
+				continue
+			}
+			deps[d] = append(deps[d], name)
+			indegree[name]++
+		}
+	}
+
+	var queue, result []string
+	for _, name := range repoNames {
+		if indegree[name] == 0 {
+			queue = append(queue, name)
+		}
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		result = append(result, n)
+		for _, consumer := range deps[n] {
+			indegree[consumer]--
+			if indegree[consumer] == 0 {
+				queue = append(queue, consumer)
+			}
+		}
+	}
+
+	if len(result) != len(repoNames) {
+		return nil, errors.New("cyclic module dependency detected among the requested repos")
+	}
+
+	return result, nil
+}
+
+// blockingConsumers returns the names of repos in the workspace (other than
+// excluded) that replace-depend on repoName.
+func (wm *WorkspaceManager) blockingConsumers(ws *Workspace, repoName string, excluded map[string]bool) []string {
+	var blockers []string
+	for name := range ws.Repos {
+		if excluded[name] || name == repoName {
+			continue
+		}
+		deps, err := wm.moduleDependencies(ws, name)
+		if err != nil {
+			continue
+		}
+		for _, d := range deps {
+			if d == repoName {
+				blockers = append(blockers, name)
+				break
+			}
+		}
+	}
+	return blockers
+}
+
+// exclusiveDependents returns every repo in the workspace that would be
+// orphaned by removing repoNames: repos whose only dependencies are
+// themselves already-orphaned, transitively back to repoNames. This is a
+// fixpoint over ws.Repos rather than a single pass, so a chain like
+// B -> A -> target (B depends only on A, A depends only on target) pulls in
+// both A and B, not just A.
+func (wm *WorkspaceManager) exclusiveDependents(ws *Workspace, repoNames []string) map[string]bool {
+	targets := map[string]bool{}
+	for _, name := range repoNames {
+		targets[name] = true
+	}
+
+	result := map[string]bool{}
+	for {
+		addedThisRound := false
+		for name := range ws.Repos {
+			if targets[name] {
+				continue
+			}
+			deps, err := wm.moduleDependencies(ws, name)
+			if err != nil || len(deps) == 0 {
+				continue
+			}
+			allTargets := true
+			for _, d := range deps {
+				if !targets[d] {
+					allTargets = false
+					break
+				}
+			}
+			if allTargets {
+				targets[name] = true
+				result[name] = true
+				addedThisRound = true
+			}
+		}
+		if !addedThisRound {
+			break
+		}
+	}
+	return result
+}
+
+// dedupeStrings returns names with duplicates removed, preserving the order
+// of each name's first occurrence.
+func dedupeStrings(names []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+func mapKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
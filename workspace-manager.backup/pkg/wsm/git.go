@@ -0,0 +1,130 @@
+package wsm
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+// createWorktree creates a git worktree for repo on branchName under the
+// workspace root and returns the resulting RepoEntry.
+func (wm *WorkspaceManager) createWorktree(ctx context.Context, ws *Workspace, repo *Repository, branchName string, force bool) (*RepoEntry, error) {
+	worktreePath := filepath.Join(ws.Root, repo.Name)
+
+	args := []string{"-C", repo.Path, "worktree", "add"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, "-B", branchName, worktreePath)
+
+	if err := runGit(ctx, args...); err != nil {
+		return nil, errors.Wrapf(err, "failed to create worktree for %q", repo.Name)
+	}
+
+	return &RepoEntry{
+		Name:         repo.Name,
+		Branch:       branchName,
+		WorktreePath: worktreePath,
+		ModulePath:   modulePath(worktreePath),
+	}, nil
+}
+
+// removeWorktree removes the git worktree backing entry, optionally forcing
+// removal over uncommitted changes and deleting the directory outright.
+func (wm *WorkspaceManager) removeWorktree(ctx context.Context, entry *RepoEntry, force, removeFiles bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, entry.WorktreePath)
+
+	if err := runGit(ctx, args...); err != nil {
+		return errors.Wrapf(err, "failed to remove worktree for %q", entry.Name)
+	}
+
+	if removeFiles {
+		if err := os.RemoveAll(entry.WorktreePath); err != nil {
+			return errors.Wrapf(err, "failed to remove directory for %q", entry.Name)
+		}
+	}
+
+	return nil
+}
+
+func runGit(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// writeGoWork regenerates the workspace's go.work file from scratch to list
+// every repo's module path as a use directive.
+func (wm *WorkspaceManager) writeGoWork(ws *Workspace) error {
+	if ws.GoWork == "" {
+		ws.GoWork = filepath.Join(ws.Root, "go.work")
+	}
+
+	work := &modfile.WorkFile{Syntax: &modfile.FileSyntax{}}
+	work.AddGoStmt("1.22")
+
+	for _, entry := range ws.Repos {
+		if entry.ModulePath == "" {
+			continue
+		}
+		relPath, err := filepath.Rel(ws.Root, entry.WorktreePath)
+		if err != nil {
+			relPath = entry.WorktreePath
+		}
+		if err := work.AddUse(relPath, entry.ModulePath); err != nil {
+			return errors.Wrapf(err, "failed to add %q to go.work", entry.Name)
+		}
+	}
+
+	return os.WriteFile(ws.GoWork, modfile.Format(work.Syntax), 0o644)
+}
+
+// snapshotGoWork returns the current on-disk bytes of the workspace's
+// go.work, and whether the file existed, so a failed operation can restore
+// it exactly instead of leaving a half-rewritten file behind.
+func (wm *WorkspaceManager) snapshotGoWork(ws *Workspace) ([]byte, bool, error) {
+	path := ws.GoWork
+	if path == "" {
+		path = filepath.Join(ws.Root, "go.work")
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// restoreGoWork writes data back as the workspace's go.work, or removes the
+// file entirely if existed is false.
+func (wm *WorkspaceManager) restoreGoWork(ws *Workspace, data []byte, existed bool) error {
+	path := ws.GoWork
+	if path == "" {
+		path = filepath.Join(ws.Root, "go.work")
+	}
+	if !existed {
+		return os.Remove(path)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// modulePath reads the module path declared in worktreePath/go.mod, or the
+// empty string if the repo has no go.mod.
+func modulePath(worktreePath string) string {
+	data, err := os.ReadFile(filepath.Join(worktreePath, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	return modfile.ModulePath(data)
+}
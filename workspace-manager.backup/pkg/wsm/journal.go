@@ -0,0 +1,76 @@
+package wsm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// operation is a single reversible mutation performed while adding or
+// removing repositories from a workspace: a git-worktree change, a
+// filesystem change, or a config/go.work rewrite.
+type operation struct {
+	description string
+	do          func(ctx context.Context) error
+	undo        func(ctx context.Context) error
+}
+
+// journal records the operations that have actually been executed so they
+// can be unwound in reverse if a later operation fails. Without this, a
+// mid-operation failure leaves the workspace half-wired: a worktree on disk
+// with no config entry, or a config entry pointing at a branch that failed
+// to create.
+type journal struct {
+	done []operation
+}
+
+func (j *journal) record(op operation) {
+	j.done = append(j.done, op)
+}
+
+// rollback replays the inverse of every recorded operation, most recently
+// executed first. It keeps going on individual undo failures rather than
+// stopping, so one stuck step doesn't prevent the rest of the cleanup.
+func (j *journal) rollback(ctx context.Context) error {
+	var errs []error
+	for i := len(j.done) - 1; i >= 0; i-- {
+		op := j.done[i]
+		if op.undo == nil {
+			continue
+		}
+		if err := op.undo(ctx); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to undo %q", op.description))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("rollback encountered %d error(s): %v", len(errs), errs)
+	}
+	return nil
+}
+
+// runOperations executes ops in order, recording each into a journal as it
+// completes. If dryRun is true, nothing is executed and the planned sequence
+// is printed instead. If any operation fails, everything executed so far is
+// rolled back and the triggering error is returned.
+func runOperations(ctx context.Context, dryRun bool, ops []operation) error {
+	if dryRun {
+		for i, op := range ops {
+			fmt.Printf("%d. %s\n", i+1, op.description)
+		}
+		return nil
+	}
+
+	j := &journal{}
+	for _, op := range ops {
+		if err := op.do(ctx); err != nil {
+			rollbackErr := j.rollback(ctx)
+			if rollbackErr != nil {
+				return errors.Wrapf(err, "operation %q failed, and rollback failed too: %v", op.description, rollbackErr)
+			}
+			return errors.Wrapf(err, "operation %q failed, rolled back", op.description)
+		}
+		j.record(op)
+	}
+	return nil
+}
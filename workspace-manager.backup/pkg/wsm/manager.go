@@ -0,0 +1,109 @@
+package wsm
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoEntry describes a single repository checked out into a workspace.
+type RepoEntry struct {
+	Name         string `yaml:"name" json:"name"`
+	Branch       string `yaml:"branch" json:"branch"`
+	WorktreePath string `yaml:"worktree_path" json:"worktree_path"`
+	ModulePath   string `yaml:"module_path,omitempty" json:"module_path,omitempty"`
+}
+
+// Workspace is the on-disk configuration for a single workspace: the set of
+// repositories checked out together on a shared branch, plus the go.work
+// that ties their modules together.
+type Workspace struct {
+	Name    string                `yaml:"name" json:"name"`
+	Branch  string                `yaml:"branch" json:"branch"`
+	Root    string                `yaml:"root" json:"root"`
+	Repos   map[string]*RepoEntry `yaml:"repos" json:"repos"`
+	GoWork  string                `yaml:"go_work_path,omitempty" json:"go_work_path,omitempty"`
+}
+
+// WorkspaceManager loads and mutates workspace configurations and the
+// git worktrees / go.work files backing them.
+type WorkspaceManager struct {
+	configDir string
+	registry  *Registry
+}
+
+// NewWorkspaceManager loads the repo registry and returns a manager rooted
+// at the user's workspace-manager config directory (~/.config/workspace-manager).
+func NewWorkspaceManager() (*WorkspaceManager, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve home directory")
+	}
+
+	configDir := filepath.Join(home, ".config", "workspace-manager")
+	registry, err := loadRegistry(filepath.Join(configDir, "registry.yaml"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load repository registry")
+	}
+
+	return &WorkspaceManager{
+		configDir: configDir,
+		registry:  registry,
+	}, nil
+}
+
+func (wm *WorkspaceManager) workspacePath(name string) string {
+	return filepath.Join(wm.configDir, "workspaces", name+".yaml")
+}
+
+func (wm *WorkspaceManager) loadWorkspace(name string) (*Workspace, error) {
+	data, err := os.ReadFile(wm.workspacePath(name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read workspace %q", name)
+	}
+
+	var ws Workspace
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse workspace %q", name)
+	}
+
+	return &ws, nil
+}
+
+// snapshotWorkspaceFile returns the current on-disk bytes of workspaceName's
+// config, and whether the file existed at all, so a failed operation can
+// restore it exactly.
+func (wm *WorkspaceManager) snapshotWorkspaceFile(name string) ([]byte, bool, error) {
+	data, err := os.ReadFile(wm.workspacePath(name))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// restoreWorkspaceFile writes data back as workspaceName's config, or
+// deletes the config file entirely if existed is false.
+func (wm *WorkspaceManager) restoreWorkspaceFile(name string, data []byte, existed bool) error {
+	if !existed {
+		return os.Remove(wm.workspacePath(name))
+	}
+	return os.WriteFile(wm.workspacePath(name), data, 0o644)
+}
+
+func (wm *WorkspaceManager) saveWorkspace(ws *Workspace) error {
+	data, err := yaml.Marshal(ws)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal workspace config")
+	}
+
+	if err := os.WriteFile(wm.workspacePath(ws.Name), data, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write workspace %q", ws.Name)
+	}
+
+	return nil
+}
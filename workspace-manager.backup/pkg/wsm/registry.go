@@ -0,0 +1,52 @@
+package wsm
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Repository is an entry in the repo registry: a known git remote that can
+// be pulled into a workspace by name.
+type Repository struct {
+	Name   string `yaml:"name" json:"name"`
+	Path   string `yaml:"path" json:"path"`
+	Remote string `yaml:"remote,omitempty" json:"remote,omitempty"`
+}
+
+// Registry is the set of repositories workspace-manager knows how to check
+// out, keyed by name.
+type Registry struct {
+	Repos map[string]*Repository `yaml:"repos"`
+}
+
+// Find looks up a repository by name, returning a wrapped error if it is
+// not registered.
+func (r *Registry) Find(name string) (*Repository, error) {
+	repo, ok := r.Repos[name]
+	if !ok {
+		return nil, errors.Errorf("repository %q is not registered", name)
+	}
+	return repo, nil
+}
+
+func loadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Registry{Repos: map[string]*Repository{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var registry Registry
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return nil, errors.Wrap(err, "failed to parse registry")
+	}
+	if registry.Repos == nil {
+		registry.Repos = map[string]*Repository{}
+	}
+
+	return &registry, nil
+}
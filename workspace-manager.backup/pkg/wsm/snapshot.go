@@ -0,0 +1,335 @@
+package wsm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SnapshotRepo is one repository's recorded state at snapshot time.
+type SnapshotRepo struct {
+	Name         string `json:"name"`
+	Branch       string `json:"branch"`
+	HeadSHA      string `json:"head_sha"`
+	WorktreePath string `json:"worktree_path"`
+}
+
+// Snapshot is a point-in-time checkpoint of a workspace: the branch, HEAD
+// SHA, and worktree path of every repo in it, plus the full go.work contents
+// needed to restore it exactly.
+type Snapshot struct {
+	Workspace string         `json:"workspace"`
+	Name      string         `json:"name"`
+	CreatedAt time.Time      `json:"created_at"`
+	Repos     []SnapshotRepo `json:"repos"`
+	GoWork    string         `json:"go_work"`
+}
+
+func (wm *WorkspaceManager) snapshotDir(workspaceName string) string {
+	return filepath.Join(wm.configDir, "snapshots", workspaceName)
+}
+
+func (wm *WorkspaceManager) snapshotPath(workspaceName, name string) string {
+	return filepath.Join(wm.snapshotDir(workspaceName), name+".json")
+}
+
+// SnapshotWorkspace records the current branch, HEAD SHA, and worktree path
+// of every repo in workspaceName, along with the full go.work contents, into
+// a timestamped JSON file under the workspace's state directory. If name is
+// empty, the snapshot is named after the current time.
+func (wm *WorkspaceManager) SnapshotWorkspace(ctx context.Context, workspaceName, name string) (*Snapshot, error) {
+	ws, err := wm.loadWorkspace(workspaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = time.Now().UTC().Format("20060102-150405")
+	}
+
+	snap := &Snapshot{
+		Workspace: workspaceName,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	repoNames := make([]string, 0, len(ws.Repos))
+	for repoName := range ws.Repos {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	for _, repoName := range repoNames {
+		entry := ws.Repos[repoName]
+		sha, err := headSHA(ctx, entry.WorktreePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read HEAD for %q", repoName)
+		}
+		snap.Repos = append(snap.Repos, SnapshotRepo{
+			Name:         repoName,
+			Branch:       entry.Branch,
+			HeadSHA:      sha,
+			WorktreePath: entry.WorktreePath,
+		})
+	}
+
+	goWork, _, err := wm.snapshotGoWork(ws)
+	if err != nil {
+		return nil, err
+	}
+	snap.GoWork = string(goWork)
+
+	if err := os.MkdirAll(wm.snapshotDir(workspaceName), 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create snapshot directory")
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal snapshot")
+	}
+	if err := os.WriteFile(wm.snapshotPath(workspaceName, name), data, 0o644); err != nil {
+		return nil, errors.Wrap(err, "failed to write snapshot")
+	}
+
+	return snap, nil
+}
+
+// LoadSnapshot loads a named snapshot of workspaceName, or the most recent
+// one if name is empty.
+func (wm *WorkspaceManager) LoadSnapshot(workspaceName, name string) (*Snapshot, error) {
+	if name == "" {
+		latest, err := wm.latestSnapshotName(workspaceName)
+		if err != nil {
+			return nil, err
+		}
+		name = latest
+	}
+
+	data, err := os.ReadFile(wm.snapshotPath(workspaceName, name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read snapshot %q for workspace %q", name, workspaceName)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse snapshot %q", name)
+	}
+
+	return &snap, nil
+}
+
+func (wm *WorkspaceManager) latestSnapshotName(workspaceName string) (string, error) {
+	entries, err := os.ReadDir(wm.snapshotDir(workspaceName))
+	if err != nil {
+		return "", errors.Wrapf(err, "no snapshots found for workspace %q", workspaceName)
+	}
+
+	// The most recently written snapshot file wins, whatever its name.
+	var latest string
+	var latestTime time.Time
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestTime) {
+			latest, latestTime = strings.TrimSuffix(e.Name(), ".json"), info.ModTime()
+		}
+	}
+	if latest == "" {
+		return "", errors.Errorf("no snapshots found for workspace %q", workspaceName)
+	}
+
+	return latest, nil
+}
+
+// RestoreWorkspace re-checks out every repo in snap to its recorded SHA,
+// creating the worktree first (via the same code path AddRepositoriesToWorkspace
+// uses) if it no longer exists, then rewrites go.work from the snapshot.
+// Like add/remove, each step is journaled as it runs; if any step fails,
+// every prior step in this call is rolled back instead of leaving some repos
+// restored and others not.
+//
+// If diff is true, nothing is applied: the changes that would be made are
+// printed instead, using GetStatusSymbol for each repo's status and for
+// go.work.
+func (wm *WorkspaceManager) RestoreWorkspace(ctx context.Context, workspaceName string, snap *Snapshot, diff bool) error {
+	ws, err := wm.loadWorkspace(workspaceName)
+	if err != nil {
+		return err
+	}
+
+	if ws.Repos == nil {
+		ws.Repos = map[string]*RepoEntry{}
+	}
+
+	if diff {
+		for _, repoSnap := range snap.Repos {
+			current, exists := ws.Repos[repoSnap.Name]
+			printSnapshotDiff(ctx, repoSnap, current, exists)
+		}
+		currentGoWork, _, err := wm.snapshotGoWork(ws)
+		if err != nil {
+			return err
+		}
+		if string(currentGoWork) != snap.GoWork {
+			fmt.Printf("%s go.work: would be rewritten from the snapshot\n", GetStatusSymbol("M"))
+		}
+		return nil
+	}
+
+	configBefore, configExisted, err := wm.snapshotWorkspaceFile(workspaceName)
+	if err != nil {
+		return err
+	}
+
+	var ops []operation
+	for _, repoSnap := range snap.Repos {
+		repoSnap := repoSnap
+
+		if _, exists := ws.Repos[repoSnap.Name]; !exists {
+			ops = append(ops, operation{
+				description: fmt.Sprintf("recreate worktree for %q on branch %s", repoSnap.Name, repoSnap.Branch),
+				do: func(ctx context.Context) error {
+					repo, err := wm.registry.Find(repoSnap.Name)
+					if err != nil {
+						return err
+					}
+					entry, err := wm.createWorktree(ctx, ws, repo, repoSnap.Branch, true)
+					if err != nil {
+						return err
+					}
+					ws.Repos[repoSnap.Name] = entry
+					return nil
+				},
+				undo: func(ctx context.Context) error {
+					entry, ok := ws.Repos[repoSnap.Name]
+					if !ok {
+						return nil
+					}
+					delete(ws.Repos, repoSnap.Name)
+					return wm.removeWorktree(ctx, entry, true, false)
+				},
+			})
+		}
+
+		var previousSHA string
+		ops = append(ops, operation{
+			description: fmt.Sprintf("git checkout --detach %s (repo %s)", repoSnap.HeadSHA, repoSnap.Name),
+			do: func(ctx context.Context) error {
+				entry := ws.Repos[repoSnap.Name]
+				sha, err := headSHA(ctx, entry.WorktreePath)
+				if err != nil {
+					return err
+				}
+				previousSHA = sha
+				if err := checkoutSHA(ctx, entry.WorktreePath, repoSnap.HeadSHA); err != nil {
+					return err
+				}
+				entry.Branch = repoSnap.Branch
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				entry, ok := ws.Repos[repoSnap.Name]
+				if !ok || previousSHA == "" {
+					return nil
+				}
+				return checkoutSHA(ctx, entry.WorktreePath, previousSHA)
+			},
+		})
+	}
+
+	var goWorkBefore []byte
+	var goWorkExisted bool
+	ops = append(ops,
+		operation{
+			description: fmt.Sprintf("rewrite go.work for workspace %q", workspaceName),
+			do: func(ctx context.Context) error {
+				before, existed, err := wm.snapshotGoWork(ws)
+				if err != nil {
+					return err
+				}
+				goWorkBefore, goWorkExisted = before, existed
+
+				goWorkPath := ws.GoWork
+				if goWorkPath == "" {
+					goWorkPath = filepath.Join(ws.Root, "go.work")
+				}
+				return os.WriteFile(goWorkPath, []byte(snap.GoWork), 0o644)
+			},
+			undo: func(ctx context.Context) error {
+				return wm.restoreGoWork(ws, goWorkBefore, goWorkExisted)
+			},
+		},
+		operation{
+			description: fmt.Sprintf("update workspace config %q", workspaceName),
+			do:          func(ctx context.Context) error { return wm.saveWorkspace(ws) },
+			undo: func(ctx context.Context) error {
+				return wm.restoreWorkspaceFile(workspaceName, configBefore, configExisted)
+			},
+		},
+	)
+
+	return runOperations(ctx, false, ops)
+}
+
+// printSnapshotDiff prints what restoring repoSnap would change, prefixing
+// the line with GetStatusSymbol's symbol for a missing worktree ("A"), a
+// different HEAD ("M") or an unreadable HEAD ("?"). A repo already at its
+// recorded SHA is printed without a symbol.
+func printSnapshotDiff(ctx context.Context, repoSnap SnapshotRepo, current *RepoEntry, exists bool) {
+	if !exists {
+		fmt.Printf("%s %s: worktree missing, would recreate on branch %s at %s\n", GetStatusSymbol("A"), repoSnap.Name, repoSnap.Branch, shortSHA(repoSnap.HeadSHA))
+		return
+	}
+
+	currentSHA, err := headSHA(ctx, current.WorktreePath)
+	if err != nil {
+		fmt.Printf("%s %s: unable to read current HEAD (%v)\n", GetStatusSymbol("?"), repoSnap.Name, err)
+		return
+	}
+
+	if currentSHA == repoSnap.HeadSHA {
+		fmt.Printf("  %s: unchanged at %s\n", repoSnap.Name, shortSHA(currentSHA))
+		return
+	}
+
+	fmt.Printf("%s %s: %s -> %s\n", GetStatusSymbol("M"), repoSnap.Name, shortSHA(currentSHA), shortSHA(repoSnap.HeadSHA))
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+func headSHA(ctx context.Context, worktreePath string) (string, error) {
+	return gitOutput(ctx, worktreePath, "rev-parse", "HEAD")
+}
+
+func checkoutSHA(ctx context.Context, worktreePath, sha string) error {
+	return runGit(ctx, "-C", worktreePath, "checkout", "--detach", sha)
+}
+
+// gitOutput runs git in dir and returns its trimmed stdout, unlike runGit
+// (which streams to the process's stdout/stderr for interactive commands).
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	fullArgs := append([]string{"-C", dir}, args...)
+	out, err := exec.CommandContext(ctx, "git", fullArgs...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}